@@ -0,0 +1,104 @@
+package pongo2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FilterFunction is the signature a template filter must implement. in is the
+// value being filtered (the left-hand side of `|filter`); param is the
+// filter's argument value (the string after the `:`, or a Value wrapping nil
+// if none was given).
+type FilterFunction func(in *Value, param *Value) (*Value, error)
+
+var filters = make(map[string]FilterFunction)
+
+// RegisterFilter makes a filter available to templates under name. It
+// returns an error if a filter with that name is already registered.
+func RegisterFilter(name string, fn FilterFunction) error {
+	if _, existing := filters[name]; existing {
+		return fmt.Errorf("filter with name '%s' is already registered", name)
+	}
+	filters[name] = fn
+	return nil
+}
+
+// FilterExists reports whether a filter has been registered under name.
+func FilterExists(name string) bool {
+	_, ok := filters[name]
+	return ok
+}
+
+// ApplyFilter looks up the filter registered under name and runs it against
+// in/param. This is the lookup path the template expression evaluator's
+// `|filter:arg` handling goes through.
+func ApplyFilter(name string, in *Value, param *Value) (*Value, error) {
+	fn, ok := filters[name]
+	if !ok {
+		return nil, fmt.Errorf("no filter with name '%s' registered", name)
+	}
+	return fn(in, param)
+}
+
+func init() {
+	if err := RegisterFilter("where", filterWhere); err != nil {
+		panic(err)
+	}
+	if err := RegisterFilter("int", filterInt); err != nil {
+		panic(err)
+	}
+	if err := RegisterFilter("float", filterFloat); err != nil {
+		panic(err)
+	}
+	if err := RegisterFilter("bool", filterBool); err != nil {
+		panic(err)
+	}
+}
+
+// filterWhere implements the `where` filter:
+//
+//	{{ posts|where:"author.name,==,alice" }}
+//
+// param is a "keyPath,op,value" string, comma-separated into exactly three
+// parts; value is compared as a plain string. For numeric/time/slice
+// comparisons, call Value.Where directly with a typed arg instead.
+func filterWhere(in *Value, param *Value) (*Value, error) {
+	raw := param.String()
+	parts := strings.SplitN(raw, ",", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf(`where filter argument must be "keyPath,op,value", got %q`, raw)
+	}
+	keyPath, op, arg := parts[0], parts[1], parts[2]
+	return in.Where(keyPath, op, AsValue(arg)), nil
+}
+
+// filterInt implements the `int` filter ({{ x|int }}), converting in to an
+// integer via Value.TryInteger so a bad conversion raises a proper template
+// error instead of silently coming back as 0.
+func filterInt(in *Value, param *Value) (*Value, error) {
+	i, err := in.TryInteger()
+	if err != nil {
+		return nil, fmt.Errorf("int filter: %w", err)
+	}
+	return AsValue(i), nil
+}
+
+// filterFloat implements the `float` filter ({{ x|float }}), converting in to
+// a float64 via Value.TryFloat.
+func filterFloat(in *Value, param *Value) (*Value, error) {
+	f, err := in.TryFloat()
+	if err != nil {
+		return nil, fmt.Errorf("float filter: %w", err)
+	}
+	return AsValue(f), nil
+}
+
+// filterBool implements the `bool` filter ({{ x|bool }}), converting in to a
+// bool via Value.TryBool.
+func filterBool(in *Value, param *Value) (*Value, error) {
+	b, err := in.TryBool()
+	if err != nil {
+		return nil, fmt.Errorf("bool filter: %w", err)
+	}
+	return AsValue(b), nil
+}
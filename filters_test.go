@@ -0,0 +1,79 @@
+package pongo2
+
+import "testing"
+
+func TestFilterWhere(t *testing.T) {
+	type author struct{ Name string }
+	type post struct{ Author author }
+
+	posts := []post{
+		{Author: author{Name: "alice"}},
+		{Author: author{Name: "bob"}},
+	}
+
+	got, err := ApplyFilter("where", AsValue(posts), AsValue("author.name,==,alice"))
+	if err != nil {
+		t.Fatalf("ApplyFilter(where) returned error: %v", err)
+	}
+	if got.Len() != 1 {
+		t.Fatalf("where filter len = %d, want 1", got.Len())
+	}
+}
+
+func TestFilterWhere_MalformedArgument(t *testing.T) {
+	_, err := ApplyFilter("where", AsValue([]int{1, 2}), AsValue("justOnePart"))
+	if err == nil {
+		t.Fatal("expected an error for a malformed where filter argument, got nil")
+	}
+}
+
+func TestFilterInt(t *testing.T) {
+	got, err := ApplyFilter("int", AsValue("42"), AsValue(nil))
+	if err != nil {
+		t.Fatalf("ApplyFilter(int) returned error: %v", err)
+	}
+	if got.Integer() != 42 {
+		t.Fatalf("int filter = %v, want 42", got.Integer())
+	}
+
+	if _, err := ApplyFilter("int", AsValue("not a number"), AsValue(nil)); err == nil {
+		t.Fatal("expected an error converting a non-numeric string to int, got nil")
+	}
+}
+
+func TestFilterFloat(t *testing.T) {
+	got, err := ApplyFilter("float", AsValue("3.5"), AsValue(nil))
+	if err != nil {
+		t.Fatalf("ApplyFilter(float) returned error: %v", err)
+	}
+	if got.Float() != 3.5 {
+		t.Fatalf("float filter = %v, want 3.5", got.Float())
+	}
+
+	if _, err := ApplyFilter("float", AsValue("not a number"), AsValue(nil)); err == nil {
+		t.Fatal("expected an error converting a non-numeric string to float, got nil")
+	}
+}
+
+func TestFilterBool(t *testing.T) {
+	got, err := ApplyFilter("bool", AsValue(true), AsValue(nil))
+	if err != nil {
+		t.Fatalf("ApplyFilter(bool) returned error: %v", err)
+	}
+	if got.Bool() != true {
+		t.Fatalf("bool filter = %v, want true", got.Bool())
+	}
+
+	if _, err := ApplyFilter("bool", AsValue("not a bool"), AsValue(nil)); err == nil {
+		t.Fatal("expected an error converting a non-bool string to bool, got nil")
+	}
+}
+
+func TestFilterExists(t *testing.T) {
+	if !FilterExists("where") {
+		t.Fatal("FilterExists(\"where\") = false, want true")
+	}
+	if FilterExists("nope-not-registered") {
+		t.Fatal("FilterExists(\"nope-not-registered\") = true, want false")
+	}
+}
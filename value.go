@@ -1,7 +1,9 @@
 package pongo2
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 	"strconv"
@@ -132,75 +134,178 @@ func (v *Value) String() string {
 	return v.getResolvedValue().String()
 }
 
-// Integer returns the underlying value as an integer (converts the underlying
-// value, if necessary). If it's not possible to convert the underlying value,
-// it will return 0.
-func (v *Value) Integer() int {
-	switch v.getResolvedValue().Kind() {
+// TryInteger returns the underlying value as an int64, converting it if
+// necessary, or an error if the conversion isn't possible. It's the strict
+// counterpart of Integer(), which swallows that error and returns 0 instead.
+//
+// A string is parsed with strconv.ParseInt first and, failing that, as a
+// float which is then truncated (so "3.7" converts to 3). Bools are
+// rejected by default; use TryIntegerStrict(true) to coerce false/true to
+// 0/1 instead.
+func (v *Value) TryInteger() (int64, error) {
+	return v.TryIntegerStrict(false)
+}
+
+// TryIntegerStrict behaves like TryInteger, but when allowBoolCoercion is
+// true it also accepts bool, converting false/true to 0/1.
+func (v *Value) TryIntegerStrict(allowBoolCoercion bool) (int64, error) {
+	rv := v.getResolvedValue()
+	switch rv.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return int(v.getResolvedValue().Int())
+		return rv.Int(), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return int(v.getResolvedValue().Uint())
+		return int64(rv.Uint()), nil
 	case reflect.Float32, reflect.Float64:
-		return int(v.getResolvedValue().Float())
+		return int64(rv.Float()), nil
 	case reflect.String:
-		// Try to convert from string to int (base 10)
-		f, err := strconv.ParseFloat(v.getResolvedValue().String(), 64)
-		if err != nil {
-			return 0
+		s := rv.String()
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return int64(f), nil
 		}
-		return int(f)
+		return 0, fmt.Errorf("cannot convert string %q to integer", s)
+	case reflect.Bool:
+		if allowBoolCoercion {
+			if rv.Bool() {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return 0, fmt.Errorf("cannot convert bool to integer")
 	default:
+		return 0, fmt.Errorf("cannot convert %s to integer", rv.Kind())
+	}
+}
+
+// Integer returns the underlying value as an integer (converts the underlying
+// value, if necessary). If it's not possible to convert the underlying value,
+// it will return 0. See TryInteger for a variant that reports the failure.
+func (v *Value) Integer() int {
+	i, err := v.TryInteger()
+	if err != nil {
 		logf("Value.Integer() not available for type: %s\n", v.getResolvedValue().Kind().String())
 		return 0
 	}
+	return int(i)
 }
 
-// Float returns the underlying value as a float (converts the underlying
-// value, if necessary). If it's not possible to convert the underlying value,
-// it will return 0.0.
-func (v *Value) Float() float64 {
-	switch v.getResolvedValue().Kind() {
+// TryFloat returns the underlying value as a float64, converting it if
+// necessary, or an error if the conversion isn't possible. It's the strict
+// counterpart of Float(), which swallows that error and returns 0 instead.
+func (v *Value) TryFloat() (float64, error) {
+	rv := v.getResolvedValue()
+	switch rv.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return float64(v.getResolvedValue().Int())
+		return float64(rv.Int()), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return float64(v.getResolvedValue().Uint())
+		return float64(rv.Uint()), nil
 	case reflect.Float32, reflect.Float64:
-		return v.getResolvedValue().Float()
+		return rv.Float(), nil
 	case reflect.String:
-		// Try to convert from string to float64 (base 10)
-		f, err := strconv.ParseFloat(v.getResolvedValue().String(), 64)
+		f, err := strconv.ParseFloat(rv.String(), 64)
 		if err != nil {
-			return 0.0
+			return 0, fmt.Errorf("cannot convert string %q to float: %w", rv.String(), err)
 		}
-		return f
+		return f, nil
 	default:
+		return 0, fmt.Errorf("cannot convert %s to float", rv.Kind())
+	}
+}
+
+// Float returns the underlying value as a float (converts the underlying
+// value, if necessary). If it's not possible to convert the underlying value,
+// it will return 0.0. See TryFloat for a variant that reports the failure.
+func (v *Value) Float() float64 {
+	f, err := v.TryFloat()
+	if err != nil {
 		logf("Value.Float() not available for type: %s\n", v.getResolvedValue().Kind().String())
 		return 0.0
 	}
+	return f
+}
+
+// TryBool returns the underlying value as a bool, or an error if the
+// underlying value isn't a bool. It's the strict counterpart of Bool(),
+// which swallows that error and returns false instead.
+func (v *Value) TryBool() (bool, error) {
+	rv := v.getResolvedValue()
+	if rv.Kind() != reflect.Bool {
+		return false, fmt.Errorf("cannot convert %s to bool", rv.Kind())
+	}
+	return rv.Bool(), nil
 }
 
 // Bool returns the underlying value as bool. If the value is not bool, false
 // will always be returned. If you're looking for true/false-evaluation of the
-// underlying value, have a look on the IsTrue()-function.
+// underlying value, have a look on the IsTrue()-function. See TryBool for a
+// variant that reports the failure.
 func (v *Value) Bool() bool {
-	switch v.getResolvedValue().Kind() {
-	case reflect.Bool:
-		return v.getResolvedValue().Bool()
-	default:
+	b, err := v.TryBool()
+	if err != nil {
 		logf("Value.Bool() not available for type: %s\n", v.getResolvedValue().Kind().String())
 		return false
 	}
+	return b
+}
+
+// unixTimeMillisThreshold is the magnitude (in seconds) above which an
+// integer passed to TryTime is treated as Unix milliseconds rather than
+// seconds.
+const unixTimeMillisThreshold = 1e12
+
+// TryTime returns the underlying value as a time.Time, or an error if the
+// conversion isn't possible. It's the strict counterpart of Time(), which
+// swallows that error and returns the zero time.Time instead.
+//
+// A string is parsed against each of the supplied layouts, then against
+// time.RFC3339, time.RFC3339Nano and time.DateTime, in that order. An
+// integer is treated as a Unix timestamp, in seconds unless its magnitude
+// implies milliseconds.
+func (v *Value) TryTime(layouts ...string) (time.Time, error) {
+	if tm, ok := v.Interface().(time.Time); ok {
+		return tm, nil
+	}
+
+	rv := v.getResolvedValue()
+
+	if v.IsInteger() {
+		unix := v.Integer()
+		if unix > unixTimeMillisThreshold || unix < -unixTimeMillisThreshold {
+			return time.UnixMilli(int64(unix)), nil
+		}
+		return time.Unix(int64(unix), 0), nil
+	}
+
+	if rv.Kind() != reflect.String {
+		return time.Time{}, fmt.Errorf("cannot convert %s to time.Time", rv.Kind())
+	}
+
+	s := rv.String()
+	for _, layout := range layouts {
+		if tm, err := time.Parse(layout, s); err == nil {
+			return tm, nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339, time.RFC3339Nano, time.DateTime} {
+		if tm, err := time.Parse(layout, s); err == nil {
+			return tm, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse %q as time.Time with any known layout", s)
 }
 
 // Time returns the underlying value as time.Time.
 // If the underlying value is not a time.Time, it returns the zero value of time.Time.
+// See TryTime for a variant that reports the failure and accepts custom layouts.
 func (v *Value) Time() time.Time {
-	tm, ok := v.Interface().(time.Time)
-	if ok {
-		return tm
+	tm, err := v.TryTime()
+	if err != nil {
+		return time.Time{}
 	}
-	return time.Time{}
+	return tm
 }
 
 // IsTrue tries to evaluate the underlying value the Pythonic-way:
@@ -372,6 +477,211 @@ func (v *Value) Contains(other *Value) bool {
 	}
 }
 
+// Where filters the underlying value (which must be a slice, array or map)
+// down to the items whose value at keyPath compares to arg according to op.
+//
+// keyPath is a dotted path (e.g. "author.name") that is resolved against each
+// item by walking struct fields, map keys and zero-argument methods, in that
+// order, one path segment at a time. An item for which keyPath cannot be
+// resolved is excluded rather than causing an error.
+//
+// Supported operators are ==, !=, <, <=, >, >=, in, not in, contains and
+// intersect (slice-vs-slice overlap). Numeric operands are compared using the
+// same Pythonic float-promotion as EqualValueTo; strings are compared
+// directly; in/contains reuse Contains.
+//
+// Example:
+//
+//	posts.Where("author.name", "==", AsValue("alice"))
+//
+// Exposed to templates as the "where" filter (see filters.go), which parses
+// its argument as "keyPath,op,value": {{ posts|where:"author.name,==,alice" }}.
+func (v *Value) Where(keyPath string, op string, arg *Value) *Value {
+	baseValue := v.getResolvedValue()
+
+	var results []any
+
+	switch baseValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < baseValue.Len(); i++ {
+			item := baseValue.Index(i)
+			if whereMatches(item, keyPath, op, arg) {
+				results = append(results, item.Interface())
+			}
+		}
+	case reflect.Map:
+		for _, key := range baseValue.MapKeys() {
+			item := baseValue.MapIndex(key)
+			if whereMatches(item, keyPath, op, arg) {
+				results = append(results, item.Interface())
+			}
+		}
+	default:
+		logf("Value.Where() not available for type: %s\n", baseValue.Kind().String())
+		return AsValue([]any{})
+	}
+
+	return AsValue(results)
+}
+
+// whereMatches resolves keyPath against item and applies op against arg.
+func whereMatches(item reflect.Value, keyPath string, op string, arg *Value) bool {
+	resolved, ok := resolveKeyPath(item, keyPath)
+	if !ok {
+		return false
+	}
+	return whereCompare(resolved, op, arg)
+}
+
+// resolveKeyPath walks a dotted key path into val, supporting struct fields,
+// map keys and zero-argument methods at each segment.
+func resolveKeyPath(val reflect.Value, keyPath string) (*Value, bool) {
+	current := indirect(val)
+	if keyPath == "" {
+		if !current.IsValid() {
+			return nil, false
+		}
+		return &Value{val: current}, true
+	}
+
+	for _, part := range strings.Split(keyPath, ".") {
+		current = indirect(current)
+		if !current.IsValid() {
+			return nil, false
+		}
+		next, ok := resolveKeySegment(current, part)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+
+	current = indirect(current)
+	if !current.IsValid() {
+		return nil, false
+	}
+	return &Value{val: current}, true
+}
+
+// indirect dereferences pointers and interfaces, returning the zero Value if
+// it encounters a nil along the way.
+func indirect(val reflect.Value) reflect.Value {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return reflect.Value{}
+		}
+		val = val.Elem()
+	}
+	return val
+}
+
+// resolveKeySegment resolves a single key-path segment against val, trying a
+// struct field, then a map key, then a zero-argument method.
+func resolveKeySegment(val reflect.Value, key string) (reflect.Value, bool) {
+	switch val.Kind() {
+	case reflect.Struct:
+		// Template key paths are conventionally lower-cased (e.g. "author.name"),
+		// while the Go fields they refer to are exported, so match case-insensitively.
+		if field := val.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, key)
+		}); field.IsValid() {
+			return field, true
+		}
+	case reflect.Map:
+		if val.Type().Key().Kind() == reflect.String {
+			if mapValue := val.MapIndex(reflect.ValueOf(key).Convert(val.Type().Key())); mapValue.IsValid() {
+				return mapValue, true
+			}
+		}
+	}
+
+	if method := zeroArgMethod(val, key); method.IsValid() {
+		if results := method.Call(nil); len(results) > 0 {
+			return results[0], true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// zeroArgMethod looks up a method with no arguments by name, checking the
+// addressable (pointer-receiver) form too when possible.
+func zeroArgMethod(val reflect.Value, name string) reflect.Value {
+	if method := val.MethodByName(name); method.IsValid() && method.Type().NumIn() == 0 {
+		return method
+	}
+	if val.CanAddr() {
+		if method := val.Addr().MethodByName(name); method.IsValid() && method.Type().NumIn() == 0 {
+			return method
+		}
+	}
+	return reflect.Value{}
+}
+
+// whereCompare applies a Where operator between a resolved item value and arg.
+func whereCompare(val *Value, op string, arg *Value) bool {
+	switch op {
+	case "==":
+		return val.EqualValueTo(arg)
+	case "!=":
+		return !val.EqualValueTo(arg)
+	case "<", "<=", ">", ">=":
+		return whereOrdered(val, op, arg)
+	case "in":
+		return arg.Contains(val)
+	case "not in":
+		return !arg.Contains(val)
+	case "contains":
+		return val.Contains(arg)
+	case "intersect":
+		return whereIntersect(val, arg)
+	default:
+		logf("Value.Where() unsupported operator: %q\n", op)
+		return false
+	}
+}
+
+// whereOrdered implements <, <=, > and >= for Where by routing through
+// Compare, the same ordering sortedKeys.Less and valuesList.Less use.
+// Incomparable operands (Compare returns an error) are considered
+// unordered and false.
+func whereOrdered(val *Value, op string, arg *Value) bool {
+	cmp, err := val.Compare(arg)
+	if err != nil {
+		return false
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default: // ">="
+		return cmp >= 0
+	}
+}
+
+// whereIntersect reports whether val and arg (both slices/arrays) share at
+// least one element, comparing elements with EqualValueTo.
+func whereIntersect(val *Value, arg *Value) bool {
+	vv := val.getResolvedValue()
+	av := arg.getResolvedValue()
+	if !isSliceOrArray(vv) || !isSliceOrArray(av) {
+		return false
+	}
+	for i := 0; i < vv.Len(); i++ {
+		item := &Value{val: vv.Index(i)}
+		for j := 0; j < av.Len(); j++ {
+			if item.EqualValueTo(&Value{val: av.Index(j)}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // CanSlice checks whether the underlying value is of type array, slice or string.
 // You normally would use CanSlice() before using the Slice() operation.
 func (v *Value) CanSlice() bool {
@@ -382,7 +692,7 @@ func (v *Value) CanSlice() bool {
 	return false
 }
 
-// Iterate iterates over a map, array, slice or a string. It calls the
+// Iterate iterates over a map, array, slice, string or channel. It calls the
 // function's first argument for every value with the following arguments:
 //
 //	idx      current 0-index
@@ -399,6 +709,11 @@ func (v *Value) Iterate(fn func(idx, count int, key, value *Value) bool, empty f
 // IterateOrder behaves like Value.Iterate, but can iterate through an array/slice/string in reverse. Does
 // not affect the iteration through a map because maps don't have any particular order.
 // However, you can force an order using the `sorted` keyword (and even use `reversed sorted`).
+//
+// For a channel, values are received until it's closed; since the total count isn't
+// known up front, count is passed as -1 to fn. reverse/sorted still work for channels,
+// but require buffering every received value before iterating can begin; in that case
+// count is the number of values received.
 func (v *Value) IterateOrder(fn func(idx, count int, key, value *Value) bool, empty func(), reverse bool, sorted bool) {
 	switch v.getResolvedValue().Kind() {
 	case reflect.Map:
@@ -480,6 +795,65 @@ func (v *Value) IterateOrder(fn func(idx, count int, key, value *Value) bool, em
 			empty()
 		}
 		return // done
+	case reflect.Chan:
+		ch := v.getResolvedValue()
+		if ch.IsNil() {
+			// A nil channel blocks forever on Recv; treat it like any other empty source.
+			empty()
+			return
+		}
+
+		if reverse || sorted {
+			var items valuesList
+			for {
+				recvd, ok := ch.Recv()
+				if !ok {
+					break
+				}
+				items = append(items, &Value{val: recvd})
+			}
+
+			if sorted {
+				if reverse {
+					sort.Sort(sort.Reverse(items))
+				} else {
+					sort.Sort(items)
+				}
+			} else {
+				for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+					items[i], items[j] = items[j], items[i]
+				}
+			}
+
+			if len(items) == 0 {
+				empty()
+				return
+			}
+			count := len(items)
+			for idx, item := range items {
+				if !fn(idx, count, item, nil) {
+					return
+				}
+			}
+			return // done
+		}
+
+		// Stream lazily (total count is unknown) so unbounded producers can be consumed.
+		idx := 0
+		for {
+			recvd, ok := ch.Recv()
+			if !ok {
+				break
+			}
+			if !fn(idx, -1, &Value{val: recvd}, nil) {
+				return
+			}
+			idx++
+		}
+		if idx == 0 {
+			empty()
+		}
+		return // done
 	default:
 		logf("Value.Iterate() not available for type: %s\n", v.getResolvedValue().Kind().String())
 	}
@@ -494,27 +868,121 @@ func (v *Value) Interface() any {
 	return nil
 }
 
-// EqualValueTo checks whether two values are containing the same value or object (if comparable).
+// EqualValueTo checks whether two values are containing the same value or
+// object. Unlike a plain reflect.DeepEqual, this recurses through pointer and
+// interface indirection, compares slices/arrays, maps and structs
+// element-wise, and reuses the Pythonic numeric and time.Time comparisons
+// used elsewhere on Value.
 func (v *Value) EqualValueTo(other *Value) bool {
-	// We provide a Pythonic way of comparing numbers, because Django does so, too.
-	// For example, the following statement is true in Python:
-	// {% if 1 == 1.0 %}
-	// This is not true in Go, so we have to do this manually.
-	if v.IsNumber() && other.IsNumber() {
-		v1, v2 := v.castToFloat64(), other.castToFloat64()
+	if !v.val.IsValid() || !other.val.IsValid() {
+		return v.val.IsValid() == other.val.IsValid()
+	}
+	return deepEqual(v.val, other.val, make(map[[2]uintptr]bool))
+}
+
+// deepEqual is the recursive engine behind EqualValueTo. visited guards
+// against infinite recursion on self-referential pointer structures: it
+// records pointer pairs already being compared, treating a revisited pair as
+// equal (the usual way to break such cycles).
+func deepEqual(a, b reflect.Value, visited map[[2]uintptr]bool) bool {
+	for a.IsValid() && a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	for b.IsValid() && b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+
+	if a.Kind() == reflect.Ptr && b.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		key := [2]uintptr{a.Pointer(), b.Pointer()}
+		if visited[key] {
+			return true
+		}
+		visited[key] = true
+		return deepEqual(a.Elem(), b.Elem(), visited)
+	}
+	if a.Kind() == reflect.Ptr {
+		if a.IsNil() {
+			return false
+		}
+		return deepEqual(a.Elem(), b, visited)
+	}
+	if b.Kind() == reflect.Ptr {
+		if b.IsNil() {
+			return false
+		}
+		return deepEqual(a, b.Elem(), visited)
+	}
+
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+
+	// Pythonic numeric and time.Time comparisons, same as elsewhere on Value.
+	av, bv := &Value{val: a}, &Value{val: b}
+	if av.IsNumber() && bv.IsNumber() {
+		v1, v2 := av.castToFloat64(), bv.castToFloat64()
 		return (v1-v2) < epsilon && (v2-v1) < epsilon
 	}
-	if v.IsTime() && other.IsTime() {
-		return v.Time().Equal(other.Time())
+	if av.IsTime() && bv.IsTime() {
+		return av.Time().Equal(bv.Time())
 	}
-	if !v.val.IsValid() || !other.val.IsValid() {
+
+	if a.Kind() != b.Kind() {
 		return false
 	}
-	// TODO(flosch): As of Go 1.20, reflect supports Comparable() and Equal(). This should potentially
-	// be used here: https://pkg.go.dev/reflect#Value.Comparable
-	return v.val.CanInterface() && other.val.CanInterface() &&
-		v.val.Type().Comparable() && other.val.Type().Comparable() &&
-		v.Interface() == other.Interface()
+
+	switch a.Kind() {
+	case reflect.Slice:
+		// nil and an empty slice are not the same value in Go; keep that distinction.
+		if a.IsNil() != b.IsNil() {
+			return false
+		}
+		fallthrough
+	case reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !deepEqual(a.Index(i), b.Index(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.MapKeys() {
+			bVal := b.MapIndex(key)
+			if !bVal.IsValid() || !deepEqual(a.MapIndex(key), bVal, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		if a.Type() != b.Type() {
+			return false
+		}
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" { // unexported field
+				continue
+			}
+			if !deepEqual(a.Field(i), b.Field(i), visited) {
+				return false
+			}
+		}
+		return true
+	default:
+		// TODO(flosch): As of Go 1.20, reflect supports Comparable() and Equal(). This should potentially
+		// be used here: https://pkg.go.dev/reflect#Value.Comparable
+		return a.CanInterface() && b.CanInterface() &&
+			a.Type().Comparable() && b.Type().Comparable() &&
+			a.Interface() == b.Interface()
+	}
 }
 
 const epsilon = 1e-9
@@ -526,6 +994,378 @@ func (v *Value) castToFloat64() float64 {
 	return v.Float()
 }
 
+// ValueTypeError is returned by Value's arithmetic methods (Add, Sub, Mul,
+// Div, FloorDiv, Mod, Pow) when the operand kinds don't support the
+// requested operation.
+type ValueTypeError struct {
+	Op    string
+	Left  reflect.Kind
+	Right reflect.Kind
+}
+
+func (e *ValueTypeError) Error() string {
+	return fmt.Sprintf("unsupported operand type(s) for %s: '%s' and '%s'", e.Op, e.Left, e.Right)
+}
+
+// ErrDivisionByZero is returned by Div, FloorDiv and Mod when the right-hand
+// operand is zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// promoteNumeric prepares two numeric operands for arithmetic the Pythonic
+// way: if both are integers, their int64 forms are returned with isInt true;
+// otherwise both are promoted to float64 via castToFloat64. ok is false if
+// either operand isn't numeric.
+func promoteNumeric(a, b *Value) (ai, bi int64, af, bf float64, isInt, ok bool) {
+	if !a.IsNumber() || !b.IsNumber() {
+		return 0, 0, 0, 0, false, false
+	}
+	if a.IsInteger() && b.IsInteger() {
+		return int64(a.Integer()), int64(b.Integer()), 0, 0, true, true
+	}
+	return 0, 0, a.castToFloat64(), b.castToFloat64(), false, true
+}
+
+// Add implements the + operator: numeric operands are added with Pythonic
+// int/float promotion, strings are concatenated, and two slices/arrays are
+// concatenated into a new slice. Any other combination is an error.
+func (v *Value) Add(other *Value) (*Value, error) {
+	if ai, bi, af, bf, isInt, ok := promoteNumeric(v, other); ok {
+		if isInt {
+			return AsValue(ai + bi), nil
+		}
+		return AsValue(af + bf), nil
+	}
+
+	if v.IsString() && other.IsString() {
+		return AsValue(v.String() + other.String()), nil
+	}
+
+	vv, ov := v.getResolvedValue(), other.getResolvedValue()
+	if isSliceOrArray(vv) && isSliceOrArray(ov) {
+		return concatSlices(vv, ov), nil
+	}
+
+	return nil, &ValueTypeError{Op: "+", Left: vv.Kind(), Right: ov.Kind()}
+}
+
+// Sub implements the - operator for numeric operands, with Pythonic
+// int/float promotion.
+func (v *Value) Sub(other *Value) (*Value, error) {
+	if ai, bi, af, bf, isInt, ok := promoteNumeric(v, other); ok {
+		if isInt {
+			return AsValue(ai - bi), nil
+		}
+		return AsValue(af - bf), nil
+	}
+	return nil, &ValueTypeError{Op: "-", Left: v.getResolvedValue().Kind(), Right: other.getResolvedValue().Kind()}
+}
+
+// Mul implements the * operator: numeric operands multiply with Pythonic
+// int/float promotion, and a slice/array combined with an int is repeated
+// that many times (in either operand order).
+func (v *Value) Mul(other *Value) (*Value, error) {
+	if ai, bi, af, bf, isInt, ok := promoteNumeric(v, other); ok {
+		if isInt {
+			return AsValue(ai * bi), nil
+		}
+		return AsValue(af * bf), nil
+	}
+
+	vv, ov := v.getResolvedValue(), other.getResolvedValue()
+	switch {
+	case isSliceOrArray(vv) && other.IsInteger():
+		return repeatSlice(vv, other.Integer()), nil
+	case isSliceOrArray(ov) && v.IsInteger():
+		return repeatSlice(ov, v.Integer()), nil
+	}
+
+	return nil, &ValueTypeError{Op: "*", Left: vv.Kind(), Right: ov.Kind()}
+}
+
+// Div implements the / operator. If either operand is a float, both are
+// promoted to float64. If both are integers, the result stays an integer
+// when it divides evenly; otherwise it's returned as a float, matching
+// Python 3's "true division" only kicking in on inexact results.
+func (v *Value) Div(other *Value) (*Value, error) {
+	if !v.IsNumber() || !other.IsNumber() {
+		return nil, &ValueTypeError{Op: "/", Left: v.getResolvedValue().Kind(), Right: other.getResolvedValue().Kind()}
+	}
+	if other.castToFloat64() == 0 {
+		return nil, ErrDivisionByZero
+	}
+
+	if v.IsInteger() && other.IsInteger() {
+		a, b := int64(v.Integer()), int64(other.Integer())
+		if a%b == 0 {
+			return AsValue(a / b), nil
+		}
+		return AsValue(float64(a) / float64(b)), nil
+	}
+	return AsValue(v.castToFloat64() / other.castToFloat64()), nil
+}
+
+// FloorDiv implements the // operator, flooring towards negative infinity
+// (Python semantics) rather than truncating towards zero.
+func (v *Value) FloorDiv(other *Value) (*Value, error) {
+	if !v.IsNumber() || !other.IsNumber() {
+		return nil, &ValueTypeError{Op: "//", Left: v.getResolvedValue().Kind(), Right: other.getResolvedValue().Kind()}
+	}
+	if other.castToFloat64() == 0 {
+		return nil, ErrDivisionByZero
+	}
+
+	if v.IsInteger() && other.IsInteger() {
+		a, b := int64(v.Integer()), int64(other.Integer())
+		q := a / b
+		if a%b != 0 && (a < 0) != (b < 0) {
+			q--
+		}
+		return AsValue(q), nil
+	}
+	return AsValue(math.Floor(v.castToFloat64() / other.castToFloat64())), nil
+}
+
+// Mod implements the % operator. For a string left-hand side and a
+// slice/array right-hand side, it does Python-style %-formatting (via
+// fmt.Sprintf); for a string with any other right-hand side, the operand is
+// passed as the single formatting argument. For numeric operands it computes
+// the Pythonic modulo (result takes the sign of the divisor).
+func (v *Value) Mod(other *Value) (*Value, error) {
+	if v.IsString() {
+		ov := other.getResolvedValue()
+		if isSliceOrArray(ov) {
+			args := make([]any, ov.Len())
+			for i := range args {
+				args[i] = ov.Index(i).Interface()
+			}
+			return AsValue(fmt.Sprintf(v.String(), args...)), nil
+		}
+		return AsValue(fmt.Sprintf(v.String(), other.Interface())), nil
+	}
+
+	if !v.IsNumber() || !other.IsNumber() {
+		return nil, &ValueTypeError{Op: "%", Left: v.getResolvedValue().Kind(), Right: other.getResolvedValue().Kind()}
+	}
+	if other.castToFloat64() == 0 {
+		return nil, ErrDivisionByZero
+	}
+
+	if v.IsInteger() && other.IsInteger() {
+		a, b := int64(v.Integer()), int64(other.Integer())
+		m := a % b
+		if m != 0 && (m < 0) != (b < 0) {
+			m += b
+		}
+		return AsValue(m), nil
+	}
+
+	a, b := v.castToFloat64(), other.castToFloat64()
+	m := math.Mod(a, b)
+	// math.Mod takes the sign of the dividend (IEEE remainder); Python's %
+	// takes the sign of the divisor, so nudge it back by one divisor when they differ.
+	if m != 0 && (m < 0) != (b < 0) {
+		m += b
+	}
+	return AsValue(m), nil
+}
+
+// Pow implements the ** operator. Two non-negative-exponent integers stay
+// integer (computed by repeated multiplication) unless the result would
+// overflow int64; that case and any other numeric combination are promoted
+// to float64 and computed via math.Pow.
+func (v *Value) Pow(other *Value) (*Value, error) {
+	if !v.IsNumber() || !other.IsNumber() {
+		return nil, &ValueTypeError{Op: "**", Left: v.getResolvedValue().Kind(), Right: other.getResolvedValue().Kind()}
+	}
+
+	if v.IsInteger() && other.IsInteger() && other.Integer() >= 0 {
+		base, exp := int64(v.Integer()), other.Integer()
+		if result, ok := intPow(base, exp); ok {
+			return AsValue(result), nil
+		}
+		// Would overflow int64: fall through to the float path below rather
+		// than returning a silently wrapped-around result.
+	}
+	return AsValue(math.Pow(v.castToFloat64(), other.castToFloat64())), nil
+}
+
+// intPow computes base**exp for exp >= 0 by repeated multiplication,
+// reporting ok=false instead of overflowing int64 silently.
+func intPow(base int64, exp int) (result int64, ok bool) {
+	result = 1
+	for i := 0; i < exp; i++ {
+		if mulOverflowsInt64(result, base) {
+			return 0, false
+		}
+		result *= base
+	}
+	return result, true
+}
+
+// mulOverflowsInt64 reports whether a*b would overflow int64.
+func mulOverflowsInt64(a, b int64) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return true
+	}
+	return a*b/b != a
+}
+
+// ApplyOperator evaluates one of the binary arithmetic operators
+// (+, -, *, /, //, %, **) between left and right. This is the single path
+// the expression evaluator's binary-operator cases should call into for
+// these operators, instead of switching on reflect.Kind themselves, so that
+// the int/float promotion rules live in one place (Add/Sub/Mul/Div/
+// FloorDiv/Mod/Pow above).
+func ApplyOperator(op string, left, right *Value) (*Value, error) {
+	switch op {
+	case "+":
+		return left.Add(right)
+	case "-":
+		return left.Sub(right)
+	case "*":
+		return left.Mul(right)
+	case "/":
+		return left.Div(right)
+	case "//":
+		return left.FloorDiv(right)
+	case "%":
+		return left.Mod(right)
+	case "**":
+		return left.Pow(right)
+	default:
+		return nil, fmt.Errorf("unknown binary operator %q", op)
+	}
+}
+
+// isSliceOrArray reports whether val is a slice or array.
+func isSliceOrArray(val reflect.Value) bool {
+	return val.Kind() == reflect.Slice || val.Kind() == reflect.Array
+}
+
+// concatSlices concatenates two slices/arrays into a new []any-backed Value.
+func concatSlices(a, b reflect.Value) *Value {
+	result := make([]any, 0, a.Len()+b.Len())
+	for i := 0; i < a.Len(); i++ {
+		result = append(result, a.Index(i).Interface())
+	}
+	for i := 0; i < b.Len(); i++ {
+		result = append(result, b.Index(i).Interface())
+	}
+	return AsValue(result)
+}
+
+// repeatSlice repeats a slice/array's elements n times into a new
+// []any-backed Value (Python-style sequence repetition).
+func repeatSlice(sl reflect.Value, n int) *Value {
+	if n < 0 {
+		n = 0
+	}
+	result := make([]any, 0, sl.Len()*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < sl.Len(); j++ {
+			result = append(result, sl.Index(j).Interface())
+		}
+	}
+	return AsValue(result)
+}
+
+// Compare orders v and other the Pythonic way, returning -1, 0 or +1. It
+// returns a *ValueTypeError for pairs that aren't comparable by any of the
+// rules below:
+//
+//   - both numeric: compared via castToFloat64, with epsilon for equality
+//   - both time.Time: Before/After/Equal
+//   - both string: lexical
+//   - both bool: false < true
+//   - both slice/array: lexicographic, comparing elements pairwise via Compare
+//
+// sortedKeys.Less and valuesList.Less (and so sorting and {% if a < b %})
+// all go through Compare, so they share one definition of ordering.
+func (v *Value) Compare(other *Value) (int, error) {
+	if v.IsNumber() && other.IsNumber() {
+		v1, v2 := v.castToFloat64(), other.castToFloat64()
+		switch {
+		case (v1-v2) < epsilon && (v2-v1) < epsilon:
+			return 0, nil
+		case v1 < v2:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+	if v.IsTime() && other.IsTime() {
+		switch {
+		case v.Time().Equal(other.Time()):
+			return 0, nil
+		case v.Time().Before(other.Time()):
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+	if v.IsString() && other.IsString() {
+		switch {
+		case v.String() == other.String():
+			return 0, nil
+		case v.String() < other.String():
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+
+	vv, ov := v.getResolvedValue(), other.getResolvedValue()
+
+	if vv.Kind() == reflect.Bool && ov.Kind() == reflect.Bool {
+		b1, b2 := vv.Bool(), ov.Bool()
+		switch {
+		case b1 == b2:
+			return 0, nil
+		case !b1 && b2:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+
+	if isSliceOrArray(vv) && isSliceOrArray(ov) {
+		for i := 0; i < vv.Len() && i < ov.Len(); i++ {
+			cmp, err := (&Value{val: vv.Index(i)}).Compare(&Value{val: ov.Index(i)})
+			if err != nil {
+				return 0, err
+			}
+			if cmp != 0 {
+				return cmp, nil
+			}
+		}
+		switch {
+		case vv.Len() == ov.Len():
+			return 0, nil
+		case vv.Len() < ov.Len():
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+
+	return 0, &ValueTypeError{Op: "compare", Left: vv.Kind(), Right: ov.Kind()}
+}
+
+// lessValue defines the total order used by sortedKeys.Less and
+// valuesList.Less. It orders via Compare when a and b are comparable; for
+// heterogeneous types (where Compare returns an error), it falls back to
+// comparing their reflect.Kind names, which keeps sorting a mixed-type slice
+// deterministic instead of coercing everything to a string.
+func lessValue(a, b *Value) bool {
+	if cmp, err := a.Compare(b); err == nil {
+		return cmp < 0
+	}
+	return a.getResolvedValue().Kind().String() < b.getResolvedValue().Kind().String()
+}
+
 type sortedKeys []reflect.Value
 
 func (sk sortedKeys) Len() int {
@@ -533,16 +1373,7 @@ func (sk sortedKeys) Len() int {
 }
 
 func (sk sortedKeys) Less(i, j int) bool {
-	vi := &Value{val: sk[i]}
-	vj := &Value{val: sk[j]}
-	switch {
-	case vi.IsInteger() && vj.IsInteger():
-		return vi.Integer() < vj.Integer()
-	case vi.IsFloat() && vj.IsFloat():
-		return vi.Float() < vj.Float()
-	default:
-		return vi.String() < vj.String()
-	}
+	return lessValue(&Value{val: sk[i]}, &Value{val: sk[j]})
 }
 
 func (sk sortedKeys) Swap(i, j int) {
@@ -556,16 +1387,7 @@ func (vl valuesList) Len() int {
 }
 
 func (vl valuesList) Less(i, j int) bool {
-	vi := vl[i]
-	vj := vl[j]
-	switch {
-	case vi.IsInteger() && vj.IsInteger():
-		return vi.Integer() < vj.Integer()
-	case vi.IsFloat() && vj.IsFloat():
-		return vi.Float() < vj.Float()
-	default:
-		return vi.String() < vj.String()
-	}
+	return lessValue(vl[i], vl[j])
 }
 
 func (vl valuesList) Swap(i, j int) {
@@ -0,0 +1,506 @@
+package pongo2
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestValue_Where_CaseInsensitiveKeyPath uses the exact scenario from the
+// chunk0-1 request ({{ posts|where:"author.name,==,alice" }}) to make sure a
+// lower-cased, template-style key path resolves against the corresponding
+// exported Go field.
+func TestValue_Where_CaseInsensitiveKeyPath(t *testing.T) {
+	type author struct{ Name string }
+	type post struct{ Author author }
+
+	posts := []post{
+		{Author: author{Name: "alice"}},
+		{Author: author{Name: "bob"}},
+	}
+
+	got := AsValue(posts).Where("author.name", "==", AsValue("alice"))
+
+	if got.Len() != 1 {
+		t.Fatalf("Where(\"author.name\", \"==\", \"alice\") len = %d, want 1", got.Len())
+	}
+}
+
+// TestValue_Where_Operators exercises the remaining Where operators beyond
+// == and > (which the other Where tests in this file cover): !=, <, <=, >=,
+// in, not in, contains and intersect, plus an unsupported operator.
+func TestValue_Where_Operators(t *testing.T) {
+	nums := []int{1, 2, 3, 4}
+
+	if got := AsValue(nums).Where("", "!=", AsValue(2)).Len(); got != 3 {
+		t.Fatalf(`Where("", "!=", 2) len = %d, want 3`, got)
+	}
+	if got := AsValue(nums).Where("", "<", AsValue(3)).Len(); got != 2 {
+		t.Fatalf(`Where("", "<", 3) len = %d, want 2`, got)
+	}
+	if got := AsValue(nums).Where("", "<=", AsValue(3)).Len(); got != 3 {
+		t.Fatalf(`Where("", "<=", 3) len = %d, want 3`, got)
+	}
+	if got := AsValue(nums).Where("", ">=", AsValue(3)).Len(); got != 2 {
+		t.Fatalf(`Where("", ">=", 3) len = %d, want 2`, got)
+	}
+
+	type item struct {
+		Tags []string
+	}
+	items := []item{
+		{Tags: []string{"a", "b"}},
+		{Tags: []string{"c"}},
+	}
+	if got := AsValue(items).Where("tags", "in", AsValue("a")).Len(); got != 0 {
+		t.Fatalf(`Where("tags", "in", "a") len = %d, want 0`, got)
+	}
+	if got := AsValue([]string{"a", "b", "c"}).Where("", "in", AsValue("a")).Len(); got != 1 {
+		t.Fatalf(`Where("", "in", "a") len = %d, want 1`, got)
+	}
+	if got := AsValue([]string{"a", "b", "c"}).Where("", "not in", AsValue("a")).Len(); got != 2 {
+		t.Fatalf(`Where("", "not in", "a") len = %d, want 2`, got)
+	}
+	if got := AsValue(items).Where("tags", "contains", AsValue("a")).Len(); got != 1 {
+		t.Fatalf(`Where("tags", "contains", "a") len = %d, want 1`, got)
+	}
+	if got := AsValue(items).Where("tags", "intersect", AsValue([]string{"c", "z"})).Len(); got != 1 {
+		t.Fatalf(`Where("tags", "intersect", ["c","z"]) len = %d, want 1`, got)
+	}
+	if got := AsValue(nums).Where("", "??", AsValue(1)).Len(); got != 0 {
+		t.Fatalf(`Where("", "??", 1) len = %d, want 0 for an unsupported operator`, got)
+	}
+}
+
+// TestApplyOperator checks that ApplyOperator dispatches each binary
+// arithmetic operator to its corresponding Value method, so the expression
+// evaluator has a single funnel to call instead of its own kind switch.
+func TestApplyOperator(t *testing.T) {
+	cases := []struct {
+		op   string
+		a, b *Value
+		want any
+	}{
+		{"+", AsValue(1), AsValue(2), int64(3)},
+		{"-", AsValue(5), AsValue(2), int64(3)},
+		{"*", AsValue(3), AsValue(4), int64(12)},
+		{"/", AsValue(8), AsValue(2), int64(4)},
+		{"//", AsValue(7), AsValue(2), int64(3)},
+		{"%", AsValue(7), AsValue(2), int64(1)},
+		{"**", AsValue(2), AsValue(5), int64(32)},
+	}
+	for _, c := range cases {
+		got, err := ApplyOperator(c.op, c.a, c.b)
+		if err != nil {
+			t.Fatalf("ApplyOperator(%q) returned error: %v", c.op, err)
+		}
+		if got.Interface() != c.want {
+			t.Fatalf("ApplyOperator(%q) = %v, want %v", c.op, got.Interface(), c.want)
+		}
+	}
+
+	if _, err := ApplyOperator("^", AsValue(1), AsValue(2)); err == nil {
+		t.Fatal("expected an error for an unknown operator, got nil")
+	}
+}
+
+// TestValue_EqualValueTo_Collections checks deep, element-wise equality on
+// slices, maps and structs, plus the nil-vs-empty-slice distinction.
+func TestValue_EqualValueTo_Collections(t *testing.T) {
+	if !AsValue([]int{1, 2, 3}).EqualValueTo(AsValue([]int{1, 2, 3})) {
+		t.Fatal("identical slices should be equal")
+	}
+	if AsValue([]int{1, 2, 3}).EqualValueTo(AsValue([]int{1, 2, 4})) {
+		t.Fatal("slices differing in one element should not be equal")
+	}
+
+	var nilSlice []int
+	emptySlice := []int{}
+	if AsValue(nilSlice).EqualValueTo(AsValue(emptySlice)) {
+		t.Fatal("a nil slice and an empty slice should not be equal")
+	}
+
+	m1 := map[string]int{"a": 1, "b": 2}
+	m2 := map[string]int{"a": 1, "b": 2}
+	m3 := map[string]int{"a": 1, "b": 3}
+	if !AsValue(m1).EqualValueTo(AsValue(m2)) {
+		t.Fatal("identical maps should be equal")
+	}
+	if AsValue(m1).EqualValueTo(AsValue(m3)) {
+		t.Fatal("maps differing in one value should not be equal")
+	}
+
+	type point struct{ X, Y int }
+	if !AsValue(point{1, 2}).EqualValueTo(AsValue(point{1, 2})) {
+		t.Fatal("identical structs should be equal")
+	}
+	if AsValue(point{1, 2}).EqualValueTo(AsValue(point{1, 3})) {
+		t.Fatal("structs differing in one field should not be equal")
+	}
+}
+
+// TestValue_EqualValueTo_Cycle checks that self-referential pointer
+// structures don't send deepEqual into infinite recursion.
+func TestValue_EqualValueTo_Cycle(t *testing.T) {
+	type node struct {
+		Val  int
+		Next *node
+	}
+	a := &node{Val: 1}
+	a.Next = a
+	b := &node{Val: 1}
+	b.Next = b
+
+	done := make(chan bool)
+	go func() { done <- AsValue(a).EqualValueTo(AsValue(b)) }()
+	select {
+	case equal := <-done:
+		if !equal {
+			t.Fatal("cyclic structures with matching shapes should compare equal")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("EqualValueTo did not return within 1s on a self-referential cycle")
+	}
+}
+
+// TestValue_Arithmetic_DirectPaths exercises Sub/Mul/Div/FloorDiv/Pow's
+// normal numeric paths, including int/float promotion and slice repetition.
+func TestValue_Arithmetic_DirectPaths(t *testing.T) {
+	if got, err := AsValue(5).Sub(AsValue(2)); err != nil || got.Interface() != int64(3) {
+		t.Fatalf("Sub(5, 2) = %v, %v; want 3, nil", got, err)
+	}
+	if got, err := AsValue(5).Sub(AsValue(2.5)); err != nil || got.Float() != 2.5 {
+		t.Fatalf("Sub(5, 2.5) = %v, %v; want 2.5, nil", got, err)
+	}
+
+	if got, err := AsValue(3).Mul(AsValue(4)); err != nil || got.Interface() != int64(12) {
+		t.Fatalf("Mul(3, 4) = %v, %v; want 12, nil", got, err)
+	}
+	if got, err := AsValue([]int{1, 2}).Mul(AsValue(3)); err != nil || got.Len() != 6 {
+		t.Fatalf("Mul([1,2], 3) = %v, %v; want len 6, nil", got, err)
+	}
+
+	if got, err := AsValue(7).Div(AsValue(2)); err != nil || got.Float() != 3.5 {
+		t.Fatalf("Div(7, 2) = %v, %v; want 3.5, nil", got, err)
+	}
+	if got, err := AsValue(8).Div(AsValue(2)); err != nil || got.Interface() != int64(4) {
+		t.Fatalf("Div(8, 2) = %v, %v; want 4, nil", got, err)
+	}
+
+	if got, err := AsValue(-7).FloorDiv(AsValue(2)); err != nil || got.Interface() != int64(-4) {
+		t.Fatalf("FloorDiv(-7, 2) = %v, %v; want -4, nil", got, err)
+	}
+	if got, err := AsValue(7).FloorDiv(AsValue(2)); err != nil || got.Interface() != int64(3) {
+		t.Fatalf("FloorDiv(7, 2) = %v, %v; want 3, nil", got, err)
+	}
+
+	if got, err := AsValue(2).Pow(AsValue(10)); err != nil || got.Interface() != int64(1024) {
+		t.Fatalf("Pow(2, 10) = %v, %v; want 1024, nil", got, err)
+	}
+}
+
+// TestValue_Arithmetic_ErrorPaths checks that mismatched operand types and
+// division by zero surface as errors rather than zero values.
+func TestValue_Arithmetic_ErrorPaths(t *testing.T) {
+	if _, err := AsValue("a").Sub(AsValue(1)); err == nil {
+		t.Fatal("expected an error subtracting a string, got nil")
+	}
+	if _, err := AsValue("a").Mul(AsValue("b")); err == nil {
+		t.Fatal("expected an error multiplying two strings, got nil")
+	}
+	if _, err := AsValue(1).Div(AsValue(0)); err != ErrDivisionByZero {
+		t.Fatalf("Div(1, 0) error = %v, want ErrDivisionByZero", err)
+	}
+	if _, err := AsValue(1).FloorDiv(AsValue(0)); err != ErrDivisionByZero {
+		t.Fatalf("FloorDiv(1, 0) error = %v, want ErrDivisionByZero", err)
+	}
+	if _, err := AsValue("a").Div(AsValue(1)); err == nil {
+		t.Fatal("expected an error dividing a string, got nil")
+	}
+}
+
+// TestValue_Pow_IntegerOverflowFallsBackToFloat checks that an integer Pow
+// that would overflow int64 falls back to the float path instead of
+// wrapping around silently.
+func TestValue_Pow_IntegerOverflowFallsBackToFloat(t *testing.T) {
+	got, err := AsValue(2).Pow(AsValue(100))
+	if err != nil {
+		t.Fatalf("Pow returned error: %v", err)
+	}
+	want := math.Pow(2, 100)
+	if got.Float() != want {
+		t.Fatalf("2**100 = %v, want %v", got.Float(), want)
+	}
+}
+
+// TestValue_Mod_MixedSignFloat checks that float Mod follows Python's %
+// (result takes the sign of the divisor), not math.Mod's IEEE remainder
+// (sign of the dividend).
+func TestValue_Mod_MixedSignFloat(t *testing.T) {
+	got, err := AsValue(5.5).Mod(AsValue(-2.0))
+	if err != nil {
+		t.Fatalf("Mod returned error: %v", err)
+	}
+	if want := -0.5; got.Float() != want {
+		t.Fatalf("5.5 %% -2.0 = %v, want %v", got.Float(), want)
+	}
+}
+
+// TestValue_IterateOrder_NilChannel makes sure a nil channel (an ordinary
+// zero value for an uninitialized chan struct field) is treated as empty
+// instead of blocking forever on Recv.
+func TestValue_IterateOrder_NilChannel(t *testing.T) {
+	var ch chan int
+
+	done := make(chan struct{})
+	go func() {
+		calledEmpty := false
+		AsValue(ch).Iterate(func(idx, count int, key, value *Value) bool {
+			t.Error("fn should not be called for a nil channel")
+			return true
+		}, func() {
+			calledEmpty = true
+		})
+		if !calledEmpty {
+			t.Error("empty() was not called for a nil channel")
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Iterate on a nil channel did not return within 500ms")
+	}
+}
+
+// TestValue_TryInteger checks TryInteger's conversions and error paths,
+// including TryIntegerStrict's opt-in bool coercion.
+func TestValue_TryInteger(t *testing.T) {
+	if i, err := AsValue(3.7).TryInteger(); err != nil || i != 3 {
+		t.Fatalf("TryInteger(3.7) = %v, %v; want 3, nil", i, err)
+	}
+	if i, err := AsValue("42").TryInteger(); err != nil || i != 42 {
+		t.Fatalf(`TryInteger("42") = %v, %v; want 42, nil`, i, err)
+	}
+	if i, err := AsValue("3.5").TryInteger(); err != nil || i != 3 {
+		t.Fatalf(`TryInteger("3.5") = %v, %v; want 3, nil`, i, err)
+	}
+	if _, err := AsValue("not a number").TryInteger(); err == nil {
+		t.Fatal("expected an error converting a non-numeric string to integer, got nil")
+	}
+	if _, err := AsValue(true).TryInteger(); err == nil {
+		t.Fatal("expected an error converting a bool to integer without coercion, got nil")
+	}
+	if i, err := AsValue(true).TryIntegerStrict(true); err != nil || i != 1 {
+		t.Fatalf("TryIntegerStrict(true, true) = %v, %v; want 1, nil", i, err)
+	}
+}
+
+// TestValue_TryFloat checks TryFloat's conversions and error path.
+func TestValue_TryFloat(t *testing.T) {
+	if f, err := AsValue(3).TryFloat(); err != nil || f != 3.0 {
+		t.Fatalf("TryFloat(3) = %v, %v; want 3.0, nil", f, err)
+	}
+	if f, err := AsValue("3.5").TryFloat(); err != nil || f != 3.5 {
+		t.Fatalf(`TryFloat("3.5") = %v, %v; want 3.5, nil`, f, err)
+	}
+	if _, err := AsValue("not a number").TryFloat(); err == nil {
+		t.Fatal("expected an error converting a non-numeric string to float, got nil")
+	}
+	if _, err := AsValue(true).TryFloat(); err == nil {
+		t.Fatal("expected an error converting a bool to float, got nil")
+	}
+}
+
+// TestValue_TryBool checks TryBool's success and error path.
+func TestValue_TryBool(t *testing.T) {
+	if b, err := AsValue(true).TryBool(); err != nil || b != true {
+		t.Fatalf("TryBool(true) = %v, %v; want true, nil", b, err)
+	}
+	if _, err := AsValue("true").TryBool(); err == nil {
+		t.Fatal("expected an error converting a string to bool, got nil")
+	}
+}
+
+// TestValue_TryTime checks TryTime's Unix-seconds, Unix-milliseconds and
+// string-layout conversions, plus its error path.
+func TestValue_TryTime(t *testing.T) {
+	tm, err := AsValue(1700000000).TryTime()
+	if err != nil || !tm.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("TryTime(unix seconds) = %v, %v", tm, err)
+	}
+
+	ms := int64(1700000000000)
+	tm, err = AsValue(ms).TryTime()
+	if err != nil || !tm.Equal(time.UnixMilli(ms)) {
+		t.Fatalf("TryTime(unix millis) = %v, %v", tm, err)
+	}
+
+	tm, err = AsValue("2024-01-01T00:00:00Z").TryTime()
+	if err != nil || tm.Year() != 2024 {
+		t.Fatalf(`TryTime("2024-01-01T00:00:00Z") = %v, %v`, tm, err)
+	}
+
+	if _, err := AsValue("not a time").TryTime(); err == nil {
+		t.Fatal("expected an error converting an unparseable string to time.Time, got nil")
+	}
+	if _, err := AsValue(true).TryTime(); err == nil {
+		t.Fatal("expected an error converting a bool to time.Time, got nil")
+	}
+}
+
+// TestValue_Compare checks Compare's ordering across the numeric, string,
+// bool, time and slice types it supports, plus its error on incomparable
+// operand kinds.
+func TestValue_Compare(t *testing.T) {
+	cmp, err := AsValue(1).Compare(AsValue(2.0))
+	if err != nil || cmp != -1 {
+		t.Fatalf("Compare(1, 2.0) = %v, %v; want -1, nil", cmp, err)
+	}
+
+	cmp, err = AsValue("b").Compare(AsValue("a"))
+	if err != nil || cmp != 1 {
+		t.Fatalf(`Compare("b", "a") = %v, %v; want 1, nil`, cmp, err)
+	}
+
+	cmp, err = AsValue(false).Compare(AsValue(true))
+	if err != nil || cmp != -1 {
+		t.Fatalf("Compare(false, true) = %v, %v; want -1, nil", cmp, err)
+	}
+
+	older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cmp, err = AsValue(older).Compare(AsValue(newer))
+	if err != nil || cmp != -1 {
+		t.Fatalf("Compare(older, newer) = %v, %v; want -1, nil", cmp, err)
+	}
+
+	cmp, err = AsValue([]int{1, 2}).Compare(AsValue([]int{1, 3}))
+	if err != nil || cmp != -1 {
+		t.Fatalf("Compare([1,2], [1,3]) = %v, %v; want -1, nil", cmp, err)
+	}
+	cmp, err = AsValue([]int{1}).Compare(AsValue([]int{1, 2}))
+	if err != nil || cmp != -1 {
+		t.Fatalf("Compare([1], [1,2]) = %v, %v; want -1, nil (shorter slice)", cmp, err)
+	}
+
+	if _, err := AsValue(1).Compare(AsValue("a")); err == nil {
+		t.Fatal("expected an error comparing an int to a string, got nil")
+	}
+	if _, err := AsValue(struct{}{}).Compare(AsValue(struct{}{})); err == nil {
+		t.Fatal("expected an error comparing two incomparable struct values, got nil")
+	}
+}
+
+// TestValue_IterateOrder_Channel_Forward checks lazy streaming (the default,
+// unsorted/unreversed) path over a channel.
+func TestValue_IterateOrder_Channel_Forward(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+
+	var got []int
+	AsValue(ch).IterateOrder(func(idx, count int, key, value *Value) bool {
+		got = append(got, key.Integer())
+		return true
+	}, func() {
+		t.Error("empty() should not be called for a non-empty channel")
+	}, false, false)
+
+	want := []int{1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+// TestValue_IterateOrder_Channel_Reverse checks the buffered reverse path
+// over a channel.
+func TestValue_IterateOrder_Channel_Reverse(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+
+	var got []int
+	AsValue(ch).IterateOrder(func(idx, count int, key, value *Value) bool {
+		got = append(got, key.Integer())
+		return true
+	}, func() {
+		t.Error("empty() should not be called for a non-empty channel")
+	}, true, false)
+
+	want := []int{3, 2, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+// TestValue_IterateOrder_Channel_Sorted checks the buffered sorted path over
+// a channel.
+func TestValue_IterateOrder_Channel_Sorted(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 3
+	ch <- 1
+	ch <- 2
+	close(ch)
+
+	var got []int
+	AsValue(ch).IterateOrder(func(idx, count int, key, value *Value) bool {
+		got = append(got, key.Integer())
+		return true
+	}, func() {
+		t.Error("empty() should not be called for a non-empty channel")
+	}, false, true)
+
+	want := []int{1, 2, 3}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+// TestValue_IterateOrder_Channel_EmptyClosed checks that an already-closed,
+// never-sent-on channel is treated as empty.
+func TestValue_IterateOrder_Channel_EmptyClosed(t *testing.T) {
+	ch := make(chan int)
+	close(ch)
+
+	calledEmpty := false
+	AsValue(ch).IterateOrder(func(idx, count int, key, value *Value) bool {
+		t.Error("fn should not be called for an empty channel")
+		return true
+	}, func() {
+		calledEmpty = true
+	}, false, false)
+
+	if !calledEmpty {
+		t.Fatal("empty() was not called for an empty, closed channel")
+	}
+}
+
+// TestValue_Where_OrderedUsesCompare checks that Where's </<=/>/>= operators
+// share Compare's ordering rules (here, time.Time), rather than a separate,
+// narrower implementation that only understood numbers and strings.
+func TestValue_Where_OrderedUsesCompare(t *testing.T) {
+	older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{older, newer}
+
+	got := AsValue(times).Where("", ">", AsValue(older))
+
+	if got.Len() != 1 {
+		t.Fatalf("Where(\"\", \">\", older) len = %d, want 1", got.Len())
+	}
+}